@@ -19,6 +19,7 @@ package topotools
 import (
 	"context"
 	"reflect"
+	"time"
 
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
@@ -28,6 +29,20 @@ import (
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
+// Kinds of vschema objects that a Tombstone can record the removal of.
+const (
+	TombstoneKindVindex        = "vindex"
+	TombstoneKindTable         = "table"
+	TombstoneKindSequence      = "sequence"
+	TombstoneKindColumnVindex  = "column_vindex"
+	TombstoneKindAutoIncrement = "auto_increment"
+)
+
+// defaultTombstoneTTL is how long a tombstone for a dropped vschema object
+// is retained before it becomes eligible for reaping by
+// ReapVSchemaTombstones.
+const defaultTombstoneTTL = 24 * time.Hour
+
 // ApplyVSchemaDDL applies the given DDL statement to the vschema
 // keyspace definition and returns the modified keyspace object.
 func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server, alterVschema *sqlparser.AlterVschema) (*topo.KeyspaceVSchemaInfo, error) {
@@ -47,6 +62,70 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 		}
 	}
 
+	return applyVSchemaDDL(ksName, ksvs, alterVschema)
+}
+
+// ApplyVSchemaDDLBatch applies a batch of DDL statements to the named
+// keyspace's VSchema as a single all-or-nothing operation and persists
+// the result to the topology server.
+//
+// The batch is applied against a snapshot of the VSchema as it existed
+// at expectedVersion: if any statement in the batch fails, or if the
+// keyspace's VSchema has since moved on from expectedVersion, the whole
+// batch is rejected and no part of it is written. This lets callers
+// coalesce several related DDL statements (for example a CREATE VINDEX
+// followed by an ADD VINDEX on the same column) into one atomic change
+// without racing other admins concurrently editing the same keyspace.
+func ApplyVSchemaDDLBatch(ctx context.Context, ksName string, topoServer *topo.Server, alterVschemas []*sqlparser.AlterVschema, expectedVersion topo.Version) (*topo.KeyspaceVSchemaInfo, error) {
+	if topoServer == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot update VSchema as the topology server connection is read-only")
+	}
+	if len(alterVschemas) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "no vschema DDL statements given for keyspace %s", ksName)
+	}
+
+	// Get the most recent version, which we'll then update.
+	ksvs, err := topoServer.GetVSchema(ctx, ksName)
+	if err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			ksvs = &topo.KeyspaceVSchemaInfo{
+				Name:     ksName,
+				Keyspace: &vschemapb.Keyspace{},
+			}
+		} else {
+			return nil, vterrors.Wrapf(err, "failed to get the current VSchema for the %s keyspace", ksName)
+		}
+	}
+	if expectedVersion != nil && (ksvs.Version == nil || ksvs.Version.String() != expectedVersion.String()) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_ABORTED, "vschema for keyspace %s has changed since it was last read; retry the batch against the latest version", ksName)
+	}
+
+	// Replay the batch against a snapshot of the keyspace VSchema so that
+	// a failure partway through leaves the original ksvs, and the topo,
+	// untouched.
+	snapshot := &topo.KeyspaceVSchemaInfo{
+		Name:     ksvs.Name,
+		Keyspace: ksvs.Keyspace.CloneVT(),
+		Version:  ksvs.Version,
+	}
+	for _, alterVschema := range alterVschemas {
+		snapshot, err = applyVSchemaDDL(ksName, snapshot, alterVschema)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "vschema DDL batch failed for keyspace %s, no changes were applied", ksName)
+		}
+	}
+
+	if err := topoServer.SaveVSchema(ctx, snapshot); err != nil {
+		return nil, vterrors.Wrapf(err, "failed to save VSchema for the %s keyspace", ksName)
+	}
+
+	return snapshot, nil
+}
+
+// applyVSchemaDDL applies a single DDL statement to the in-memory
+// KeyspaceVSchemaInfo, returning the (possibly same) modified object. It
+// does not read from or write to the topology server.
+func applyVSchemaDDL(ksName string, ksvs *topo.KeyspaceVSchemaInfo, alterVschema *sqlparser.AlterVschema) (*topo.KeyspaceVSchemaInfo, error) {
 	if ksvs.Tables == nil {
 		ksvs.Tables = map[string]*vschemapb.Table{}
 	}
@@ -81,6 +160,7 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 			Params: params,
 			Owner:  owner,
 		}
+		clearTombstone(ksvs, TombstoneKindVindex, name)
 
 		return ksvs, nil
 
@@ -100,6 +180,7 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 		}
 
 		delete(ksvs.Vindexes, name)
+		recordTombstone(ksvs, TombstoneKindVindex, name)
 
 		return ksvs, nil
 
@@ -114,6 +195,7 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 		}
 
 		ksvs.Tables[name] = &vschemapb.Table{}
+		clearTombstone(ksvs, TombstoneKindTable, name)
 
 		return ksvs, nil
 
@@ -124,6 +206,7 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 		}
 
 		delete(ksvs.Tables, name)
+		recordTombstone(ksvs, TombstoneKindTable, name)
 
 		return ksvs, nil
 
@@ -161,6 +244,12 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 					Params: params,
 					Owner:  owner,
 				}
+				// This statement also binds the vindex to a column below,
+				// which bumps the Revision once for the whole statement;
+				// just drop any stale tombstone here without bumping again.
+				if ksvs.Tombstones != nil {
+					delete(ksvs.Tombstones, tombstoneKey(TombstoneKindVindex, name))
+				}
 			}
 		} else {
 			if _, ok := ksvs.Vindexes[name]; !ok {
@@ -193,6 +282,12 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 			Columns: columns,
 		})
 		ksvs.Tables[tableName] = table
+		// This binding bumps the Revision once for the whole statement
+		// below; just drop any stale tombstone here without bumping again.
+		if ksvs.Tombstones != nil {
+			delete(ksvs.Tombstones, tombstoneKey(TombstoneKindColumnVindex, tableName+"."+name))
+		}
+		bumpRevision(ksvs)
 
 		return ksvs, nil
 
@@ -209,6 +304,7 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 				if len(table.ColumnVindexes) == 0 {
 					delete(ksvs.Tables, tableName)
 				}
+				recordTombstone(ksvs, TombstoneKindColumnVindex, tableName+"."+name)
 				return ksvs, nil
 			}
 		}
@@ -225,6 +321,7 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 		}
 
 		ksvs.Tables[name] = &vschemapb.Table{Type: "sequence"}
+		clearTombstone(ksvs, TombstoneKindSequence, name)
 
 		return ksvs, nil
 
@@ -239,6 +336,7 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 		}
 
 		delete(ksvs.Tables, name)
+		recordTombstone(ksvs, TombstoneKindSequence, name)
 
 		return ksvs, nil
 
@@ -257,6 +355,7 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 			Column:   alterVschema.AutoIncSpec.Column.String(),
 			Sequence: sqlparser.String(alterVschema.AutoIncSpec.Sequence),
 		}
+		clearTombstone(ksvs, TombstoneKindAutoIncrement, name)
 
 		return ksvs, nil
 
@@ -272,9 +371,55 @@ func ApplyVSchemaDDL(ctx context.Context, ksName string, topoServer *topo.Server
 		}
 
 		table.AutoIncrement = nil
+		recordTombstone(ksvs, TombstoneKindAutoIncrement, name)
 
 		return ksvs, nil
 	}
 
 	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected vindex ddl operation %s", alterVschema.Action.ToString())
 }
+
+// bumpRevision advances the keyspace-scoped Revision counter. It is
+// called on every mutation (create or delete) so that VSchema
+// subscribers can tell, between two polls, that something changed even
+// if the object they cared about was deleted and a same-named object
+// was later created: the revision never slides backwards.
+func bumpRevision(ksvs *topo.KeyspaceVSchemaInfo) {
+	ksvs.Revision++
+}
+
+// tombstoneKey is the map key under which a kind/name pair's tombstone is
+// stored in KeyspaceVSchemaInfo.Tombstones.
+func tombstoneKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// recordTombstone bumps the Revision and leaves a tombstone marking the
+// removal of the named object of the given kind, so that a watcher that
+// only sees periodic snapshots of the VSchema can still notice the
+// deletion.
+func recordTombstone(ksvs *topo.KeyspaceVSchemaInfo, kind, name string) {
+	bumpRevision(ksvs)
+	if ksvs.Tombstones == nil {
+		ksvs.Tombstones = map[string]*vschemapb.Tombstone{}
+	}
+	ksvs.Tombstones[tombstoneKey(kind, name)] = &vschemapb.Tombstone{
+		Kind:      kind,
+		Name:      name,
+		Revision:  ksvs.Revision,
+		DroppedAt: time.Now().Unix(),
+		Ttl:       int64(defaultTombstoneTTL.Seconds()),
+	}
+}
+
+// clearTombstone bumps the Revision and removes any tombstone left
+// behind by a previous drop of the named object, so that re-creating
+// something under a recently-dropped name doesn't leave watchers
+// thinking it's still gone.
+func clearTombstone(ksvs *topo.KeyspaceVSchemaInfo, kind, name string) {
+	bumpRevision(ksvs)
+	if ksvs.Tombstones == nil {
+		return
+	}
+	delete(ksvs.Tombstones, tombstoneKey(kind, name))
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func parseAlterVschema(t *testing.T, sql string) *sqlparser.AlterVschema {
+	t.Helper()
+	stmt, err := sqlparser.Parse(sql)
+	require.NoError(t, err)
+	ddl, ok := stmt.(*sqlparser.AlterVschema)
+	require.Truef(t, ok, "expected *sqlparser.AlterVschema, got %T", stmt)
+	return ddl
+}
+
+// seedEmptyVSchema saves an empty VSchema for ksName so that a later
+// GetVSchema call (and the version it returns) has something to read.
+func seedEmptyVSchema(t *testing.T, ctx context.Context, ts *topo.Server, ksName string) *topo.KeyspaceVSchemaInfo {
+	t.Helper()
+	require.NoError(t, ts.SaveVSchema(ctx, &topo.KeyspaceVSchemaInfo{Name: ksName, Keyspace: &vschemapb.Keyspace{}}))
+	ksvs, err := ts.GetVSchema(ctx, ksName)
+	require.NoError(t, err)
+	return ksvs
+}
+
+func TestApplyVSchemaDDLBatch(t *testing.T) {
+	ctx := context.Background()
+	const ksName = "test_keyspace"
+
+	t.Run("coalesces a create vindex and an add vindex into one save", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+
+		ksvs := seedEmptyVSchema(t, ctx, ts, ksName)
+
+		batch := []*sqlparser.AlterVschema{
+			parseAlterVschema(t, "alter vschema create vindex my_vdx using hash"),
+			parseAlterVschema(t, "alter vschema on t1 add vindex my_vdx (id)"),
+		}
+		got, err := ApplyVSchemaDDLBatch(ctx, ksName, ts, batch, ksvs.Version)
+		require.NoError(t, err)
+		assert.True(t, got.Sharded)
+		assert.Contains(t, got.Vindexes, "my_vdx")
+		assert.Contains(t, got.Tables, "t1")
+
+		saved, err := ts.GetVSchema(ctx, ksName)
+		require.NoError(t, err)
+		assert.Contains(t, saved.Vindexes, "my_vdx")
+	})
+
+	t.Run("rejects the whole batch and leaves the topo untouched if any statement fails", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+
+		ksvs := seedEmptyVSchema(t, ctx, ts, ksName)
+
+		batch := []*sqlparser.AlterVschema{
+			parseAlterVschema(t, "alter vschema create vindex my_vdx using hash"),
+			parseAlterVschema(t, "alter vschema drop vindex does_not_exist"),
+		}
+		_, err := ApplyVSchemaDDLBatch(ctx, ksName, ts, batch, ksvs.Version)
+		require.Error(t, err)
+
+		after, err := ts.GetVSchema(ctx, ksName)
+		require.NoError(t, err)
+		assert.NotContains(t, after.Vindexes, "my_vdx")
+	})
+
+	t.Run("rejects a batch whose expected version has moved on", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+
+		ksvs := seedEmptyVSchema(t, ctx, ts, ksName)
+		staleVersion := ksvs.Version
+
+		// Simulate a concurrent admin saving a change after we read.
+		ksvs.Sharded = true
+		require.NoError(t, ts.SaveVSchema(ctx, ksvs))
+
+		batch := []*sqlparser.AlterVschema{
+			parseAlterVschema(t, "alter vschema create vindex my_vdx using hash"),
+		}
+		_, err := ApplyVSchemaDDLBatch(ctx, ksName, ts, batch, staleVersion)
+		require.Error(t, err)
+		assert.Equal(t, vtrpcpb.Code_ABORTED, vterrors.Code(err))
+
+		after, err := ts.GetVSchema(ctx, ksName)
+		require.NoError(t, err)
+		assert.NotContains(t, after.Vindexes, "my_vdx")
+	})
+
+	t.Run("clears the stale column-vindex tombstone when a dropped binding is re-added", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+
+		ksvs := seedEmptyVSchema(t, ctx, ts, ksName)
+		setup := []*sqlparser.AlterVschema{
+			parseAlterVschema(t, "alter vschema create vindex my_vdx using hash"),
+			parseAlterVschema(t, "alter vschema on t1 add vindex my_vdx (id)"),
+			parseAlterVschema(t, "alter vschema on t1 drop vindex my_vdx"),
+		}
+		_, err := ApplyVSchemaDDLBatch(ctx, ksName, ts, setup, ksvs.Version)
+		require.NoError(t, err)
+
+		dropped, err := ts.GetVSchema(ctx, ksName)
+		require.NoError(t, err)
+		require.Contains(t, dropped.Tombstones, "column_vindex/t1.my_vdx")
+
+		_, err = ApplyVSchemaDDLBatch(ctx, ksName, ts, []*sqlparser.AlterVschema{
+			parseAlterVschema(t, "alter vschema on t1 add vindex my_vdx (id)"),
+		}, dropped.Version)
+		require.NoError(t, err)
+
+		after, err := ts.GetVSchema(ctx, ksName)
+		require.NoError(t, err)
+		assert.NotContains(t, after.Tombstones, "column_vindex/t1.my_vdx")
+	})
+
+	t.Run("rejects an empty batch", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+
+		ksvs := seedEmptyVSchema(t, ctx, ts, ksName)
+
+		_, err := ApplyVSchemaDDLBatch(ctx, ksName, ts, nil, ksvs.Version)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2019 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"context"
+	"sort"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// VSchemaDiff describes the projected effect of an ApplyVSchemaDDLDryRun
+// call, so that a caller like vtctldclient can render a human-readable
+// preview ("vschema plan") before committing the real DDL.
+type VSchemaDiff struct {
+	AddedVindexes   []string
+	RemovedVindexes []string
+
+	AddedTables   []string
+	RemovedTables []string
+
+	// AddedColumnVindexes and RemovedColumnVindexes describe the
+	// table/vindex bindings added or removed by ADD VINDEX / DROP
+	// VINDEX ON statements.
+	AddedColumnVindexes   []ColumnVindexRef
+	RemovedColumnVindexes []ColumnVindexRef
+
+	// AddedAutoIncrement and RemovedAutoIncrement are table names whose
+	// auto-increment definition was added or removed.
+	AddedAutoIncrement   []string
+	RemovedAutoIncrement []string
+
+	// ShardedFlipped is true if this DDL flips the keyspace's Sharded
+	// bit from false to true, which happens automatically the first
+	// time a vindex is defined in an unsharded keyspace.
+	ShardedFlipped bool
+}
+
+// ColumnVindexRef identifies a column-vindex binding by the table it's
+// defined on and the vindex it binds, kept as separate fields (rather
+// than a single joined string) since either name may itself contain a
+// "." when backtick-quoted.
+type ColumnVindexRef struct {
+	Table  string
+	Vindex string
+}
+
+// ApplyVSchemaDDLDryRun projects the effect of applying alterVschema to
+// the named keyspace without writing anything to the topology server. It
+// returns the KeyspaceVSchemaInfo as it would look after the DDL, along
+// with a structured diff against the current VSchema.
+func ApplyVSchemaDDLDryRun(ctx context.Context, ksName string, topoServer *topo.Server, alterVschema *sqlparser.AlterVschema) (*topo.KeyspaceVSchemaInfo, *VSchemaDiff, error) {
+	if topoServer == nil {
+		return nil, nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot plan VSchema changes as the topology server connection is read-only")
+	}
+
+	before, err := topoServer.GetVSchema(ctx, ksName)
+	if err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			before = &topo.KeyspaceVSchemaInfo{
+				Name:     ksName,
+				Keyspace: &vschemapb.Keyspace{},
+			}
+		} else {
+			return nil, nil, vterrors.Wrapf(err, "failed to get the current VSchema for the %s keyspace", ksName)
+		}
+	}
+
+	after := &topo.KeyspaceVSchemaInfo{
+		Name:     before.Name,
+		Keyspace: before.Keyspace.CloneVT(),
+		Version:  before.Version,
+	}
+	after, err = applyVSchemaDDL(ksName, after, alterVschema)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return after, diffKeyspaceVSchema(before, after), nil
+}
+
+// diffKeyspaceVSchema computes the structured diff between two
+// KeyspaceVSchemaInfo snapshots of the same keyspace.
+func diffKeyspaceVSchema(before, after *topo.KeyspaceVSchemaInfo) *VSchemaDiff {
+	diff := &VSchemaDiff{ShardedFlipped: !before.Sharded && after.Sharded}
+
+	for name := range after.Vindexes {
+		if _, ok := before.Vindexes[name]; !ok {
+			diff.AddedVindexes = append(diff.AddedVindexes, name)
+		}
+	}
+	for name := range before.Vindexes {
+		if _, ok := after.Vindexes[name]; !ok {
+			diff.RemovedVindexes = append(diff.RemovedVindexes, name)
+		}
+	}
+
+	for name := range after.Tables {
+		if _, ok := before.Tables[name]; !ok {
+			diff.AddedTables = append(diff.AddedTables, name)
+		}
+	}
+	for name := range before.Tables {
+		if _, ok := after.Tables[name]; !ok {
+			diff.RemovedTables = append(diff.RemovedTables, name)
+		}
+	}
+
+	tableNames := make(map[string]bool, len(before.Tables)+len(after.Tables))
+	for name := range before.Tables {
+		tableNames[name] = true
+	}
+	for name := range after.Tables {
+		tableNames[name] = true
+	}
+	for tableName := range tableNames {
+		beforeVindexes := columnVindexNames(before.Tables[tableName])
+		afterVindexes := columnVindexNames(after.Tables[tableName])
+		for name := range afterVindexes {
+			if !beforeVindexes[name] {
+				diff.AddedColumnVindexes = append(diff.AddedColumnVindexes, ColumnVindexRef{Table: tableName, Vindex: name})
+			}
+		}
+		for name := range beforeVindexes {
+			if !afterVindexes[name] {
+				diff.RemovedColumnVindexes = append(diff.RemovedColumnVindexes, ColumnVindexRef{Table: tableName, Vindex: name})
+			}
+		}
+
+		beforeAutoInc := before.Tables[tableName] != nil && before.Tables[tableName].AutoIncrement != nil
+		afterAutoInc := after.Tables[tableName] != nil && after.Tables[tableName].AutoIncrement != nil
+		if afterAutoInc && !beforeAutoInc {
+			diff.AddedAutoIncrement = append(diff.AddedAutoIncrement, tableName)
+		}
+		if beforeAutoInc && !afterAutoInc {
+			diff.RemovedAutoIncrement = append(diff.RemovedAutoIncrement, tableName)
+		}
+	}
+
+	sort.Strings(diff.AddedVindexes)
+	sort.Strings(diff.RemovedVindexes)
+	sort.Strings(diff.AddedTables)
+	sort.Strings(diff.RemovedTables)
+	sortColumnVindexRefs(diff.AddedColumnVindexes)
+	sortColumnVindexRefs(diff.RemovedColumnVindexes)
+	sort.Strings(diff.AddedAutoIncrement)
+	sort.Strings(diff.RemovedAutoIncrement)
+
+	return diff
+}
+
+// sortColumnVindexRefs orders refs by table then vindex name, giving the
+// diff a stable, human-readable order for "vschema plan" output.
+func sortColumnVindexRefs(refs []ColumnVindexRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Table != refs[j].Table {
+			return refs[i].Table < refs[j].Table
+		}
+		return refs[i].Vindex < refs[j].Vindex
+	})
+}
+
+// columnVindexNames returns the set of column-vindex names bound to a
+// table, tolerating a nil table (not yet defined in the VSchema).
+func columnVindexNames(table *vschemapb.Table) map[string]bool {
+	names := make(map[string]bool)
+	if table == nil {
+		return names
+	}
+	for _, cv := range table.ColumnVindexes {
+		names[cv.Name] = true
+	}
+	return names
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func ksvsWith(keyspace *vschemapb.Keyspace) *topo.KeyspaceVSchemaInfo {
+	return &topo.KeyspaceVSchemaInfo{Name: "test_keyspace", Keyspace: keyspace}
+}
+
+func TestApplyVSchemaDDLDryRun(t *testing.T) {
+	ctx := context.Background()
+	const ksName = "test_keyspace"
+
+	t.Run("projects the change without writing to the topo", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+		seedEmptyVSchema(t, ctx, ts, ksName)
+
+		projected, diff, err := ApplyVSchemaDDLDryRun(ctx, ksName, ts, parseAlterVschema(t, "alter vschema create vindex my_vdx using hash"))
+		require.NoError(t, err)
+		assert.True(t, projected.Sharded)
+		assert.Contains(t, projected.Vindexes, "my_vdx")
+		assert.Equal(t, []string{"my_vdx"}, diff.AddedVindexes)
+		assert.True(t, diff.ShardedFlipped)
+
+		after, err := ts.GetVSchema(ctx, ksName)
+		require.NoError(t, err)
+		assert.False(t, after.Sharded)
+		assert.NotContains(t, after.Vindexes, "my_vdx")
+	})
+
+	t.Run("surfaces the underlying error without writing anything", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+		seedEmptyVSchema(t, ctx, ts, ksName)
+
+		_, _, err := ApplyVSchemaDDLDryRun(ctx, ksName, ts, parseAlterVschema(t, "alter vschema drop vindex does_not_exist"))
+		assert.Error(t, err)
+	})
+}
+
+func TestDiffKeyspaceVSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		before *vschemapb.Keyspace
+		after  *vschemapb.Keyspace
+		want   *VSchemaDiff
+	}{
+		{
+			name:   "added and removed vindexes",
+			before: &vschemapb.Keyspace{Vindexes: map[string]*vschemapb.Vindex{"old": {}}},
+			after:  &vschemapb.Keyspace{Vindexes: map[string]*vschemapb.Vindex{"new": {}}},
+			want:   &VSchemaDiff{AddedVindexes: []string{"new"}, RemovedVindexes: []string{"old"}},
+		},
+		{
+			name:   "added and removed tables",
+			before: &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"old_t": {}}},
+			after:  &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"new_t": {}}},
+			want:   &VSchemaDiff{AddedTables: []string{"new_t"}, RemovedTables: []string{"old_t"}},
+		},
+		{
+			name:   "added and removed column vindex bindings on the same table",
+			before: &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "old_vdx"}}}}},
+			after:  &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "new_vdx"}}}}},
+			want: &VSchemaDiff{
+				AddedColumnVindexes:   []ColumnVindexRef{{Table: "t1", Vindex: "new_vdx"}},
+				RemovedColumnVindexes: []ColumnVindexRef{{Table: "t1", Vindex: "old_vdx"}},
+			},
+		},
+		{
+			name:   "added and removed auto-increment",
+			before: &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"t1": {}, "t2": {AutoIncrement: &vschemapb.AutoIncrement{Column: "id"}}}},
+			after:  &vschemapb.Keyspace{Tables: map[string]*vschemapb.Table{"t1": {AutoIncrement: &vschemapb.AutoIncrement{Column: "id"}}, "t2": {}}},
+			want:   &VSchemaDiff{AddedAutoIncrement: []string{"t1"}, RemovedAutoIncrement: []string{"t2"}},
+		},
+		{
+			name:   "sharded bit flips on first vindex",
+			before: &vschemapb.Keyspace{Sharded: false},
+			after:  &vschemapb.Keyspace{Sharded: true, Vindexes: map[string]*vschemapb.Vindex{"v1": {}}},
+			want:   &VSchemaDiff{AddedVindexes: []string{"v1"}, ShardedFlipped: true},
+		},
+		{
+			name:   "no changes yields an empty diff",
+			before: &vschemapb.Keyspace{Vindexes: map[string]*vschemapb.Vindex{"v1": {}}},
+			after:  &vschemapb.Keyspace{Vindexes: map[string]*vschemapb.Vindex{"v1": {}}},
+			want:   &VSchemaDiff{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffKeyspaceVSchema(ksvsWith(tt.before), ksvsWith(tt.after))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
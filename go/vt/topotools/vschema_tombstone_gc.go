@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// ReapVSchemaTombstones removes tombstones whose TTL has elapsed from the
+// named keyspace's VSchema. It holds the keyspace lock for the duration
+// of the read-modify-write, which serializes it with other holders of
+// that lock; ApplyVSchemaDDL and ApplyVSchemaDDLBatch do not take the
+// keyspace lock. It only writes back to the topo when it actually has
+// something to reap.
+func ReapVSchemaTombstones(ctx context.Context, topoServer *topo.Server, ksName string) (reaped int, err error) {
+	ctx, unlock, lockErr := topoServer.LockKeyspace(ctx, ksName, "ReapVSchemaTombstones")
+	if lockErr != nil {
+		return 0, lockErr
+	}
+	defer unlock(&err)
+
+	ksvs, err := topoServer.GetVSchema(ctx, ksName)
+	if err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			return 0, nil
+		}
+		return 0, vterrors.Wrapf(err, "failed to get the current VSchema for the %s keyspace", ksName)
+	}
+
+	now := time.Now().Unix()
+	for key, tombstone := range ksvs.Tombstones {
+		if now-tombstone.DroppedAt < tombstone.Ttl {
+			continue
+		}
+		delete(ksvs.Tombstones, key)
+		reaped++
+	}
+	if reaped == 0 {
+		return 0, nil
+	}
+
+	if err := topoServer.SaveVSchema(ctx, ksvs); err != nil {
+		return 0, vterrors.Wrapf(err, "failed to save VSchema for the %s keyspace after reaping tombstones", ksName)
+	}
+
+	return reaped, nil
+}
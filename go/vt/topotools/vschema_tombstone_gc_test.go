@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestReapVSchemaTombstones(t *testing.T) {
+	ctx := context.Background()
+	const ksName = "test_keyspace"
+
+	t.Run("reaps tombstones at or past their TTL, keeps fresher ones", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+
+		ksvs := seedEmptyVSchema(t, ctx, ts, ksName)
+		now := time.Now().Unix()
+		ksvs.Tombstones = map[string]*vschemapb.Tombstone{
+			"vindex/long_gone": {Kind: TombstoneKindVindex, Name: "long_gone", DroppedAt: now - 7200, Ttl: 3600},
+			// Exactly at the TTL boundary: should also be reaped.
+			"vindex/at_boundary": {Kind: TombstoneKindVindex, Name: "at_boundary", DroppedAt: now - 3600, Ttl: 3600},
+			"vindex/fresh":       {Kind: TombstoneKindVindex, Name: "fresh", DroppedAt: now, Ttl: 3600},
+		}
+		require.NoError(t, ts.SaveVSchema(ctx, ksvs))
+
+		reaped, err := ReapVSchemaTombstones(ctx, ts, ksName)
+		require.NoError(t, err)
+		assert.Equal(t, 2, reaped)
+
+		after, err := ts.GetVSchema(ctx, ksName)
+		require.NoError(t, err)
+		assert.NotContains(t, after.Tombstones, "vindex/long_gone")
+		assert.NotContains(t, after.Tombstones, "vindex/at_boundary")
+		assert.Contains(t, after.Tombstones, "vindex/fresh")
+	})
+
+	t.Run("is a no-op and does not bump the version when nothing has expired", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+
+		ksvs := seedEmptyVSchema(t, ctx, ts, ksName)
+		ksvs.Tombstones = map[string]*vschemapb.Tombstone{
+			"vindex/fresh": {Kind: TombstoneKindVindex, Name: "fresh", DroppedAt: time.Now().Unix(), Ttl: 3600},
+		}
+		require.NoError(t, ts.SaveVSchema(ctx, ksvs))
+		before, err := ts.GetVSchema(ctx, ksName)
+		require.NoError(t, err)
+
+		reaped, err := ReapVSchemaTombstones(ctx, ts, ksName)
+		require.NoError(t, err)
+		assert.Equal(t, 0, reaped)
+
+		after, err := ts.GetVSchema(ctx, ksName)
+		require.NoError(t, err)
+		assert.Equal(t, before.Version, after.Version)
+	})
+
+	t.Run("returns no error for a keyspace with no VSchema yet", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+
+		reaped, err := ReapVSchemaTombstones(ctx, ts, "no_such_keyspace")
+		require.NoError(t, err)
+		assert.Equal(t, 0, reaped)
+	})
+
+	t.Run("fails if the keyspace is already locked by someone else", func(t *testing.T) {
+		ts := memorytopo.NewServer(ctx, "zone1")
+		defer ts.Close()
+		seedEmptyVSchema(t, ctx, ts, ksName)
+
+		var lockErr error
+		_, unlock, err := ts.LockKeyspace(ctx, ksName, "TestReapVSchemaTombstones")
+		require.NoError(t, err)
+		defer unlock(&lockErr)
+
+		shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err = ReapVSchemaTombstones(shortCtx, ts, ksName)
+		assert.Error(t, err)
+	})
+}